@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/metrics"
+)
+
+// Dispatcher fans a port change out to multiple Targets concurrently. A failure applying to one
+// target is logged and reflected in that target's Health, and does not stop port updates
+// reaching the others -- but it is also aggregated into Apply's return value, so a PortSink
+// caller can tell the update wasn't fully applied. Run then keeps retrying degraded targets with
+// the last-known port, since Gluetun may not report a new port again for a long time.
+type Dispatcher struct {
+	targets []Target
+	retry   RetryPolicy
+	metrics *metrics.Metrics
+
+	mu      sync.Mutex
+	port    uint16
+	hasPort bool
+}
+
+// NewDispatcher builds a Dispatcher over targets, retrying a failed apply per retry.
+func NewDispatcher(targets []Target, retry RetryPolicy, m *metrics.Metrics) *Dispatcher {
+	return &Dispatcher{targets: targets, retry: retry, metrics: m}
+}
+
+// Apply implements PortSink, applying port to every target in parallel. It returns an aggregate
+// of every target's error, if any target failed even after its retry budget was exhausted.
+func (d *Dispatcher) Apply(ctx context.Context, port uint16) error {
+	d.mu.Lock()
+	d.port, d.hasPort = port, true
+	d.mu.Unlock()
+
+	return d.applyTo(ctx, d.targets, port)
+}
+
+// Run periodically retries applying the last port Apply saw to any targets still degraded, so a
+// qBittorrent outage that outlasts a single Apply's retry budget is not abandoned forever --
+// Gluetun commonly leaves the forwarded port unchanged for weeks, so without this a degraded
+// target would otherwise never be retried again. Run blocks until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			d.reconcile(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) reconcile(ctx context.Context) {
+	d.mu.Lock()
+	port, hasPort := d.port, d.hasPort
+	d.mu.Unlock()
+	if !hasPort {
+		return
+	}
+
+	var degraded []Target
+	for _, target := range d.targets {
+		if target.Health().State == HealthDegraded {
+			degraded = append(degraded, target)
+		}
+	}
+	if len(degraded) == 0 {
+		return
+	}
+
+	logger.Info("retrying degraded targets", slog.Int("targets", len(degraded)), slog.Int("port", int(port)))
+	if err := d.applyTo(ctx, degraded, port); err != nil {
+		logger.Error("degraded targets still failing to apply forwarded port", slog.Any("err", err))
+	}
+}
+
+func (d *Dispatcher) applyTo(ctx context.Context, targets []Target, port uint16) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(targets))
+	wg.Add(len(targets))
+
+	for i, target := range targets {
+		i, target := i, target
+		go func() {
+			defer wg.Done()
+			err := d.retry.Do(ctx, func() error {
+				return target.Apply(ctx, port)
+			})
+			d.metrics.RecordQBTUpdate(target.Name(), err)
+			if err != nil {
+				logger.Error("failed to apply forwarded port to target",
+					slog.String("target", target.Name()), slog.Any("err", err))
+				errs[i] = err
+			}
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/metrics"
+)
+
+// fakeTarget is a Target double that records every port it was asked to apply, and optionally
+// fails, so Dispatcher's fan-out and reconciliation can be tested without a real qBittorrent
+// instance. Its Health tracks applies the same way qbtTarget's does, so Dispatcher.Run can
+// observe degraded fakes.
+type fakeTarget struct {
+	name string
+	err  error
+
+	mu      sync.Mutex
+	applied []uint16
+	health  Health
+}
+
+func (f *fakeTarget) Name() string { return f.name }
+
+func (f *fakeTarget) Apply(ctx context.Context, port uint16) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.applied = append(f.applied, port)
+	if f.err != nil {
+		f.health.State = HealthDegraded
+		f.health.ConsecutiveFailures++
+		f.health.LastError = f.err
+	} else {
+		f.health.State = HealthHealthy
+		f.health.ConsecutiveFailures = 0
+		f.health.LastError = nil
+	}
+	return f.err
+}
+
+func (f *fakeTarget) CurrentListenPort(ctx context.Context) (uint16, error) { return 0, nil }
+
+func (f *fakeTarget) Health() Health {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.health
+}
+
+func (f *fakeTarget) appliedPorts() []uint16 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]uint16(nil), f.applied...)
+}
+
+func TestDispatcherAppliesToEveryTarget(t *testing.T) {
+	healthy := &fakeTarget{name: "healthy"}
+	failing := &fakeTarget{name: "failing", err: errors.New("boom")}
+
+	d := NewDispatcher([]Target{healthy, failing}, RetryPolicy{MaxAttempts: 1}, metrics.New(0))
+
+	err := d.Apply(context.Background(), 51413)
+	if !errors.Is(err, failing.err) {
+		t.Fatalf("Apply() error = %v, want it to wrap %v", err, failing.err)
+	}
+
+	if got := healthy.appliedPorts(); len(got) != 1 || got[0] != 51413 {
+		t.Errorf("healthy target applied = %v, want [51413]", got)
+	}
+	if got := failing.appliedPorts(); len(got) != 1 || got[0] != 51413 {
+		t.Errorf("failing target applied = %v, want [51413] (one target failing must not stop the others)", got)
+	}
+}
+
+func TestDispatcherRunRetriesDegradedTargets(t *testing.T) {
+	failing := &fakeTarget{name: "failing", err: errors.New("boom")}
+	d := NewDispatcher([]Target{failing}, RetryPolicy{MaxAttempts: 1}, metrics.New(0))
+
+	if err := d.Apply(context.Background(), 51413); err == nil {
+		t.Fatal("Apply() error = nil, want an error")
+	}
+	if got := len(failing.appliedPorts()); got != 1 {
+		t.Fatalf("applied attempts after Apply() = %d, want 1", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	d.Run(ctx, 10*time.Millisecond)
+
+	if got := len(failing.appliedPorts()); got <= 1 {
+		t.Errorf("applied attempts after Run() = %d, want more than 1 (degraded target should be retried)", got)
+	}
+}
+
+func TestTargetHealthTransitions(t *testing.T) {
+	target := &qbtTarget{name: "test"}
+
+	if got := target.Health().State; got != HealthUnknown {
+		t.Fatalf("initial state = %v, want %v", got, HealthUnknown)
+	}
+
+	target.recordResult(errors.New("boom"))
+	h := target.Health()
+	if h.State != HealthDegraded {
+		t.Errorf("state after failure = %v, want %v", h.State, HealthDegraded)
+	}
+	if h.ConsecutiveFailures != 1 {
+		t.Errorf("ConsecutiveFailures after 1 failure = %d, want 1", h.ConsecutiveFailures)
+	}
+	if h.LastError == nil {
+		t.Error("LastError after failure = nil, want non-nil")
+	}
+
+	target.recordResult(errors.New("boom again"))
+	if got := target.Health().ConsecutiveFailures; got != 2 {
+		t.Errorf("ConsecutiveFailures after 2 failures = %d, want 2", got)
+	}
+
+	target.recordResult(nil)
+	h = target.Health()
+	if h.State != HealthHealthy {
+		t.Errorf("state after success = %v, want %v", h.State, HealthHealthy)
+	}
+	if h.ConsecutiveFailures != 0 {
+		t.Errorf("ConsecutiveFailures after success = %d, want 0", h.ConsecutiveFailures)
+	}
+	if h.LastError != nil {
+		t.Errorf("LastError after success = %v, want nil", h.LastError)
+	}
+	if h.LastSuccess.IsZero() {
+		t.Error("LastSuccess after success = zero, want non-zero")
+	}
+}
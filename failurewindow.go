@@ -0,0 +1,41 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// FailureWindow tracks how long a dependency has been failing continuously, so a caller can
+// keep retrying silently through brief blips and only escalate (e.g. to a fatal exit) once
+// failures have persisted for at least max.
+type FailureWindow struct {
+	max time.Duration
+
+	mu    sync.Mutex
+	since time.Time
+}
+
+// NewFailureWindow builds a FailureWindow that considers a dependency down once it has failed
+// continuously for max.
+func NewFailureWindow(max time.Duration) *FailureWindow {
+	return &FailureWindow{max: max}
+}
+
+// RecordFailure marks a failure and reports whether the window has now been failing
+// continuously for at least max.
+func (w *FailureWindow) RecordFailure() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.since.IsZero() {
+		w.since = time.Now()
+		return false
+	}
+	return time.Since(w.since) >= w.max
+}
+
+// RecordSuccess resets the failure window.
+func (w *FailureWindow) RecordSuccess() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.since = time.Time{}
+}
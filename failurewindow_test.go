@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailureWindowEscalatesAfterMax(t *testing.T) {
+	w := NewFailureWindow(20 * time.Millisecond)
+
+	if w.RecordFailure() {
+		t.Fatal("RecordFailure() = true on first failure, want false")
+	}
+	if w.RecordFailure() {
+		t.Fatal("RecordFailure() = true before max has elapsed, want false")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !w.RecordFailure() {
+		t.Fatal("RecordFailure() = false after failing continuously for longer than max, want true")
+	}
+}
+
+func TestFailureWindowResetsOnSuccess(t *testing.T) {
+	w := NewFailureWindow(20 * time.Millisecond)
+
+	w.RecordFailure()
+	time.Sleep(25 * time.Millisecond)
+	w.RecordSuccess()
+
+	if w.RecordFailure() {
+		t.Fatal("RecordFailure() = true immediately after a success reset the window, want false")
+	}
+}
@@ -0,0 +1,59 @@
+// Package retry implements capped exponential backoff with full jitter, shared by the root
+// package's Gluetun/qBittorrent retries and pkg/qbittorrent's re-authentication retry.
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Policy bounds retries of a transient operation using capped exponential backoff with full
+// jitter: sleep = rand() * min(max, base * 2^attempt).
+type Policy struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+
+	// Retryable reports whether a failed attempt should be retried. A nil Retryable retries
+	// every error; set it to stop early on errors that retrying can never fix.
+	Retryable func(err error) bool
+}
+
+// Do runs fn, retrying with capped exponential backoff and full jitter between attempts until
+// it succeeds, returns a non-retryable error, MaxAttempts is exhausted, or ctx is cancelled.
+func (p Policy) Do(ctx context.Context, fn func() error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if p.Retryable != nil && !p.Retryable(err) {
+			return err
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		select {
+		case <-time.After(p.backoff(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+func (p Policy) backoff(attempt int) time.Duration {
+	backoff := time.Duration(float64(p.Base) * math.Pow(2, float64(attempt)))
+	if backoff <= 0 || backoff > p.Max {
+		backoff = p.Max
+	}
+	return time.Duration(rand.Float64() * float64(backoff))
+}
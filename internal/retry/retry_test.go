@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	attempts := 0
+	p := Policy{Base: time.Millisecond, Max: time.Millisecond, MaxAttempts: 5}
+
+	err := p.Do(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestDoGivesUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	wantErr := errors.New("persistent")
+	p := Policy{Base: time.Millisecond, Max: time.Millisecond, MaxAttempts: 3}
+
+	err := p.Do(context.Background(), func() error {
+		attempts++
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Do() error = %v, want %v", err, wantErr)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestDoStopsOnNonRetryableError(t *testing.T) {
+	attempts := 0
+	wantErr := errors.New("not retryable")
+	p := Policy{
+		Base:        time.Millisecond,
+		Max:         time.Millisecond,
+		MaxAttempts: 5,
+		Retryable:   func(err error) bool { return false },
+	}
+
+	err := p.Do(context.Background(), func() error {
+		attempts++
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Do() error = %v, want %v", err, wantErr)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 (no retry after a non-retryable error)", attempts)
+	}
+}
+
+func TestDoStopsOnContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := Policy{Base: time.Second, Max: time.Second, MaxAttempts: 5}
+	err := p.Do(ctx, func() error { return errors.New("transient") })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	p := Policy{Base: time.Second, Max: 5 * time.Second}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		if got := p.backoff(attempt); got > p.Max {
+			t.Errorf("backoff(%d) = %s, want <= %s", attempt, got, p.Max)
+		}
+	}
+}
+
+func TestBackoffGrowsWithAttempt(t *testing.T) {
+	p := Policy{Base: time.Millisecond, Max: time.Hour}
+
+	// With full jitter the backoff is random, but its ceiling (base * 2^attempt) should grow,
+	// so sample many draws and compare the maximum observed sleep at each attempt.
+	maxAt := func(attempt int) time.Duration {
+		var max time.Duration
+		for i := 0; i < 200; i++ {
+			if d := p.backoff(attempt); d > max {
+				max = d
+			}
+		}
+		return max
+	}
+
+	if maxAt(0) > maxAt(4) {
+		t.Errorf("max backoff did not grow from attempt 0 to attempt 4")
+	}
+}
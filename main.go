@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/config"
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/metrics"
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/qbittorrent"
+)
+
+const (
+	PORT_SOURCE_KEY        = "PORT_SOURCE"
+	WEBHOOK_ADDR_KEY       = "WEBHOOK_ADDR"
+	QBT_SKIP_VERIFY_KEY    = "QBT_TLS_SKIP_VERIFY"
+	CONFIG_FILE_KEY        = "CONFIG_FILE"
+	METRICS_ADDR_KEY       = "METRICS_ADDR"
+	FAILURE_THRESHOLD_KEY  = "READINESS_FAILURE_THRESHOLD"
+	RETRY_BASE_KEY         = "RETRY_BASE"
+	RETRY_MAX_KEY          = "RETRY_MAX"
+	RETRY_MAX_ATTEMPTS_KEY = "RETRY_MAX_ATTEMPTS"
+	FATAL_AFTER_KEY        = "FATAL_AFTER"
+	RECONCILE_INTERVAL_KEY = "RECONCILE_INTERVAL"
+)
+
+var (
+	portSourceMode    = getEnvOrDefault(PORT_SOURCE_KEY, "gluetun-poll")
+	webhookAddr       = getEnvOrDefault(WEBHOOK_ADDR_KEY, ":9000")
+	qbtSkipVerify     = getEnvOrDefault(QBT_SKIP_VERIFY_KEY, "false")
+	configFile        = getEnvOrDefault(CONFIG_FILE_KEY, "")
+	metricsAddr       = getEnvOrDefault(METRICS_ADDR_KEY, ":9100")
+	failureThreshold  = getEnvOrDefault(FAILURE_THRESHOLD_KEY, "5")
+	retryBase         = getEnvOrDefault(RETRY_BASE_KEY, "1s")
+	retryMax          = getEnvOrDefault(RETRY_MAX_KEY, "30s")
+	retryMaxAttempts  = getEnvOrDefault(RETRY_MAX_ATTEMPTS_KEY, "5")
+	fatalAfter        = getEnvOrDefault(FATAL_AFTER_KEY, "10m")
+	reconcileInterval = getEnvOrDefault(RECONCILE_INTERVAL_KEY, "1m")
+)
+
+// retryPolicyFromEnv builds the shared RetryPolicy used to retry transient Gluetun and
+// qBittorrent failures, from the RETRY_* environment variables.
+func retryPolicyFromEnv() (RetryPolicy, error) {
+	base, err := time.ParseDuration(retryBase)
+	if err != nil {
+		return RetryPolicy{}, fmt.Errorf("invalid %s: %w", RETRY_BASE_KEY, err)
+	}
+	max, err := time.ParseDuration(retryMax)
+	if err != nil {
+		return RetryPolicy{}, fmt.Errorf("invalid %s: %w", RETRY_MAX_KEY, err)
+	}
+	maxAttempts, err := strconv.Atoi(retryMaxAttempts)
+	if err != nil {
+		return RetryPolicy{}, fmt.Errorf("invalid %s: %w", RETRY_MAX_ATTEMPTS_KEY, err)
+	}
+	return RetryPolicy{Base: base, Max: max, MaxAttempts: maxAttempts}, nil
+}
+
+func newPortSource(interval time.Duration, m *metrics.Metrics, retry RetryPolicy, fatalAfter time.Duration) (PortSource, error) {
+	switch portSourceMode {
+	case "gluetun-poll":
+		return NewGluetunPoller(gluetunUrl, interval, m, retry, fatalAfter), nil
+	case "webhook":
+		return NewWebhookSource(webhookAddr), nil
+	default:
+		return nil, fmt.Errorf("unknown %s %q, expected \"gluetun-poll\" or \"webhook\"", PORT_SOURCE_KEY, portSourceMode)
+	}
+}
+
+// buildTargets constructs the qBittorrent targets this process manages: the targets listed in
+// CONFIG_FILE if set, or else a single target built from the QBT_* environment variables.
+func buildTargets(ctx context.Context, m *metrics.Metrics) ([]Target, error) {
+	if configFile != "" {
+		return buildTargetsFromConfig(ctx, configFile, m)
+	}
+	return buildTargetsFromEnv(ctx, m)
+}
+
+func buildTargetsFromEnv(ctx context.Context, m *metrics.Metrics) ([]Target, error) {
+	skipVerify, err := strconv.ParseBool(qbtSkipVerify)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", QBT_SKIP_VERIFY_KEY, err)
+	}
+
+	logger.Info("Checking if qBittorrent is up")
+	waitForConnUp(fmt.Sprintf("%s:%s", qBittorrentAddress, qBittorrentApiPort))
+
+	client, err := qbittorrent.New(qbittorrent.Config{
+		BaseURL:    qBittorrentUrl,
+		Username:   qBittorrentUser,
+		Password:   qBittorrentPassword,
+		SkipVerify: skipVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := loginIfNeeded(ctx, client, "default", qBittorrentUser, m); err != nil {
+		return nil, err
+	}
+
+	return []Target{NewTarget("default", client, qbittorrent.TorrentFilter{})}, nil
+}
+
+func buildTargetsFromConfig(ctx context.Context, path string, m *metrics.Metrics) ([]Target, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]Target, 0, len(cfg.Targets))
+	for _, tc := range cfg.Targets {
+		logger.Info("Checking if qBittorrent is up", slog.String("target", tc.Name))
+		waitForConnUp(fmt.Sprintf("%s:%s", tc.Host, tc.Port))
+
+		client, err := qbittorrent.New(qbittorrent.Config{
+			BaseURL:    fmt.Sprintf("http://%s:%s", tc.Host, tc.Port),
+			Username:   tc.Username,
+			Password:   tc.Password,
+			SkipVerify: tc.TLSSkipVerify,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", tc.Name, err)
+		}
+		if err := loginIfNeeded(ctx, client, tc.Name, tc.Username, m); err != nil {
+			return nil, fmt.Errorf("target %s: %w", tc.Name, err)
+		}
+
+		targets = append(targets, NewTarget(tc.Name, client, qbittorrent.TorrentFilter{
+			Categories: tc.Filter.Categories,
+			Tags:       tc.Filter.Tags,
+			Hashes:     tc.Filter.Hashes,
+		}))
+	}
+	return targets, nil
+}
+
+func loginIfNeeded(ctx context.Context, client *qbittorrent.Client, targetName, username string, m *metrics.Metrics) error {
+	// qBittorrent allows skipping auth for local clients.
+	// Assume this is intended if no username is set.
+	if username == "" {
+		return nil
+	}
+	err := client.Login(ctx)
+	m.RecordLogin(targetName, err)
+	return err
+}
+
+func startMetricsServer(ctx context.Context, addr string, m *metrics.Metrics) {
+	srv := &http.Server{Addr: addr, Handler: m.Handler()}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	go func() {
+		logger.Info("serving metrics", slog.String("addr", addr))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics server stopped", slog.Any("err", err))
+		}
+	}()
+}
+
+func main() {
+	interval := flag.Duration("interval", time.Second, "how often to poll Gluetun for the forwarded port (gluetun-poll mode only)")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	threshold, err := strconv.Atoi(failureThreshold)
+	if err != nil {
+		log.Fatalf("invalid %s: %s", FAILURE_THRESHOLD_KEY, err.Error())
+	}
+	m := metrics.New(threshold)
+	startMetricsServer(ctx, metricsAddr, m)
+
+	retry, err := retryPolicyFromEnv()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	fatalAfterDuration, err := time.ParseDuration(fatalAfter)
+	if err != nil {
+		log.Fatalf("invalid %s: %s", FATAL_AFTER_KEY, err.Error())
+	}
+	reconcileIntervalDuration, err := time.ParseDuration(reconcileInterval)
+	if err != nil {
+		log.Fatalf("invalid %s: %s", RECONCILE_INTERVAL_KEY, err.Error())
+	}
+
+	if portSourceMode == "gluetun-poll" {
+		logger.Info("Checking if Gluetun is up")
+		waitForConnUp(fmt.Sprintf("%s:%s", gluetunAddress, gluetunApiPort))
+	}
+
+	targets, err := buildTargets(ctx, m)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	// currentPort seeds the syncer's notion of the last-applied port with the lowest port
+	// currently set across all targets, so a restart doesn't needlessly re-apply a port that's
+	// already correct everywhere -- and so a target that's out of sync with the others is still
+	// forced through on the very first observed port, rather than silently left stale.
+	var currentPort uint16
+	for i, target := range targets {
+		port, err := target.CurrentListenPort(ctx)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		logger.Info("Current port set in qBittorrent", slog.String("target", target.Name()), slog.Int("qbt-port", int(port)))
+		if i == 0 || port < currentPort {
+			currentPort = port
+		}
+	}
+
+	source, err := newPortSource(*interval, m, retry, fatalAfterDuration)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	dispatcher := NewDispatcher(targets, retry, m)
+	go dispatcher.Run(ctx, reconcileIntervalDuration)
+
+	syncer := NewPortSyncer(source, dispatcher, m)
+	syncer.SeedLastPort(currentPort)
+
+	logger.Info("starting the port-forward watcher",
+		slog.String("source", portSourceMode), slog.Int("targets", len(targets)))
+
+	if err := syncer.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Fatal(err.Error())
+	}
+}
@@ -0,0 +1,61 @@
+// Package config loads the YAML configuration used to manage multiple qBittorrent targets.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Filter gates a Target's instance-wide listen_port update on whether any torrent matching
+// Categories, Tags, or Hashes currently exists on that instance. qBittorrent's listen_port is a
+// single global preference, so this is an all-or-nothing gate, not per-torrent scoping: when the
+// filter matches nothing, the update is skipped entirely; when it matches anything, every
+// torrent on the instance is affected equally.
+type Filter struct {
+	Categories []string `yaml:"categories"`
+	Tags       []string `yaml:"tags"`
+	Hashes     []string `yaml:"hashes"`
+}
+
+// TargetConfig describes one qBittorrent instance to manage.
+type TargetConfig struct {
+	Name          string `yaml:"name"`
+	Host          string `yaml:"host"`
+	Port          string `yaml:"port"`
+	Username      string `yaml:"username"`
+	Password      string `yaml:"password"`
+	TLSSkipVerify bool   `yaml:"tls_skip_verify"`
+	Filter        Filter `yaml:"filter"`
+}
+
+// Config is the top-level configuration file format.
+type Config struct {
+	Targets []TargetConfig `yaml:"targets"`
+}
+
+// Load reads and parses the YAML config file at path.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing config file: %w", err)
+	}
+	if len(cfg.Targets) == 0 {
+		return Config{}, fmt.Errorf("config file %s defines no targets", path)
+	}
+	for i, t := range cfg.Targets {
+		if t.Name == "" {
+			return Config{}, fmt.Errorf("target %d is missing a name", i)
+		}
+		if t.Host == "" {
+			return Config{}, fmt.Errorf("target %q is missing a host", t.Name)
+		}
+	}
+	return cfg, nil
+}
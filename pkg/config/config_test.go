@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("writing test config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		contents  string
+		wantErr   bool
+		wantCount int
+	}{
+		{
+			name: "valid single target",
+			contents: `
+targets:
+  - name: seedbox1
+    host: localhost
+    port: "8080"
+    username: admin
+    password: adminadmin
+`,
+			wantCount: 1,
+		},
+		{
+			name: "valid multiple targets with filters",
+			contents: `
+targets:
+  - name: seedbox1
+    host: localhost
+    port: "8080"
+    filter:
+      categories: [public]
+  - name: seedbox2
+    host: 10.0.0.2
+    port: "8081"
+    tls_skip_verify: true
+    filter:
+      tags: [vpn-forward]
+      hashes: [abc123]
+`,
+			wantCount: 2,
+		},
+		{
+			name:     "no targets",
+			contents: "targets: []",
+			wantErr:  true,
+		},
+		{
+			name: "target missing name",
+			contents: `
+targets:
+  - host: localhost
+    port: "8080"
+`,
+			wantErr: true,
+		},
+		{
+			name: "target missing host",
+			contents: `
+targets:
+  - name: seedbox1
+    port: "8080"
+`,
+			wantErr: true,
+		},
+		{
+			name:     "invalid yaml",
+			contents: "targets: [",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.contents)
+
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(cfg.Targets) != tt.wantCount {
+				t.Errorf("len(cfg.Targets) = %d, want %d", len(cfg.Targets), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("Load() error = nil, want an error for a missing file")
+	}
+}
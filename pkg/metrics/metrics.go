@@ -0,0 +1,145 @@
+// Package metrics exposes Prometheus collectors and a readiness check for the port-sync loop.
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "gluetun_qbt_port_updater"
+
+// Metrics holds the Prometheus collectors this project exposes and tracks consecutive
+// Gluetun/qBittorrent failures so readiness can flip when either becomes unreachable.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	portChangesObserved prometheus.Counter
+	qbtUpdateSuccess    *prometheus.CounterVec
+	qbtUpdateFailure    *prometheus.CounterVec
+	qbtLogins           *prometheus.CounterVec
+	gluetunErrors       prometheus.Counter
+	currentPort         prometheus.Gauge
+	lastSyncTimestamp   prometheus.Gauge
+
+	failureThreshold int
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+}
+
+// New builds a Metrics with all collectors registered against a fresh registry. Readiness
+// flips to not-ready once failureThreshold consecutive Gluetun or qBittorrent failures have
+// been recorded, and flips back on the next success. A non-positive failureThreshold disables
+// the readiness check (always ready).
+func New(failureThreshold int) *Metrics {
+	m := &Metrics{
+		Registry:         prometheus.NewRegistry(),
+		failureThreshold: failureThreshold,
+
+		portChangesObserved: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "port_changes_observed_total",
+			Help:      "Forwarded-port changes observed from the configured PortSource.",
+		}),
+		qbtUpdateSuccess: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "qbt_update_success_total",
+			Help:      "Successful qBittorrent listen-port updates, by target.",
+		}, []string{"target"}),
+		qbtUpdateFailure: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "qbt_update_failure_total",
+			Help:      "Failed qBittorrent listen-port updates, by target.",
+		}, []string{"target"}),
+		qbtLogins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "qbt_login_total",
+			Help:      "qBittorrent login attempts, by target and outcome.",
+		}, []string{"target", "outcome"}),
+		gluetunErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "gluetun_errors_total",
+			Help:      "Errors encountered fetching the forwarded port from Gluetun.",
+		}),
+		currentPort: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "current_forwarded_port",
+			Help:      "The most recently applied forwarded port.",
+		}),
+		lastSyncTimestamp: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_sync_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful port sync to any target.",
+		}),
+	}
+
+	m.Registry.MustRegister(
+		m.portChangesObserved,
+		m.qbtUpdateSuccess,
+		m.qbtUpdateFailure,
+		m.qbtLogins,
+		m.gluetunErrors,
+		m.currentPort,
+		m.lastSyncTimestamp,
+	)
+
+	return m
+}
+
+// RecordPortObserved records a forwarded-port change observed from the PortSource.
+func (m *Metrics) RecordPortObserved(port uint16) {
+	m.portChangesObserved.Inc()
+	m.currentPort.Set(float64(port))
+}
+
+// RecordGluetunError records a failed attempt to fetch the forwarded port from Gluetun.
+func (m *Metrics) RecordGluetunError() {
+	m.gluetunErrors.Inc()
+	m.recordFailure()
+}
+
+// RecordGluetunSuccess records a successful fetch from Gluetun.
+func (m *Metrics) RecordGluetunSuccess() {
+	m.recordSuccess()
+}
+
+// RecordQBTUpdate records the outcome of applying a forwarded port to target.
+func (m *Metrics) RecordQBTUpdate(target string, err error) {
+	if err != nil {
+		m.qbtUpdateFailure.WithLabelValues(target).Inc()
+		m.recordFailure()
+		return
+	}
+	m.qbtUpdateSuccess.WithLabelValues(target).Inc()
+	m.lastSyncTimestamp.SetToCurrentTime()
+	m.recordSuccess()
+}
+
+// RecordLogin records a qBittorrent login attempt for target.
+func (m *Metrics) RecordLogin(target string, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	m.qbtLogins.WithLabelValues(target, outcome).Inc()
+}
+
+func (m *Metrics) recordFailure() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.consecutiveFailures++
+}
+
+func (m *Metrics) recordSuccess() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.consecutiveFailures = 0
+}
+
+// Ready reports whether consecutive Gluetun/qBittorrent failures are below the threshold.
+func (m *Metrics) Ready() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.failureThreshold <= 0 || m.consecutiveFailures < m.failureThreshold
+}
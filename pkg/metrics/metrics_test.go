@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadyReflectsConsecutiveFailures(t *testing.T) {
+	m := New(2)
+
+	if !m.Ready() {
+		t.Fatal("Ready() = false before any failures, want true")
+	}
+
+	m.RecordGluetunError()
+	if !m.Ready() {
+		t.Fatal("Ready() = false after 1 failure with threshold 2, want true")
+	}
+
+	m.RecordGluetunError()
+	if m.Ready() {
+		t.Fatal("Ready() = true after 2 failures with threshold 2, want false")
+	}
+
+	m.RecordGluetunSuccess()
+	if !m.Ready() {
+		t.Fatal("Ready() = false after a success resets the streak, want true")
+	}
+}
+
+func TestReadyDisabledWithNonPositiveThreshold(t *testing.T) {
+	m := New(0)
+
+	for i := 0; i < 10; i++ {
+		m.RecordGluetunError()
+	}
+	if !m.Ready() {
+		t.Fatal("Ready() = false with threshold 0, want true (readiness check disabled)")
+	}
+}
+
+func TestRecordQBTUpdateTracksFailuresAcrossTargets(t *testing.T) {
+	m := New(2)
+	testErr := errors.New("update failed")
+
+	m.RecordQBTUpdate("seedbox1", testErr)
+	m.RecordQBTUpdate("seedbox2", testErr)
+	if m.Ready() {
+		t.Fatal("Ready() = true after 2 failures across different targets, want false")
+	}
+
+	m.RecordQBTUpdate("seedbox1", nil)
+	if !m.Ready() {
+		t.Fatal("Ready() = false after a success resets the streak, want true")
+	}
+}
@@ -0,0 +1,183 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := New(Config{BaseURL: srv.URL, Username: "admin", Password: "adminadmin"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return client
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantErr    bool
+	}{
+		{name: "success", statusCode: http.StatusOK, body: "Ok.", wantErr: false},
+		{name: "rejected credentials", statusCode: http.StatusOK, body: "Fails.", wantErr: true},
+		{name: "server error", statusCode: http.StatusInternalServerError, body: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			})
+
+			err := client.Login(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Login() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetPreferences(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Preferences{ListenPort: 51413})
+	})
+
+	prefs, err := client.GetPreferences(context.Background())
+	if err != nil {
+		t.Fatalf("GetPreferences() error = %v", err)
+	}
+	if prefs.ListenPort != 51413 {
+		t.Errorf("ListenPort = %d, want 51413", prefs.ListenPort)
+	}
+}
+
+func TestSetPreferences(t *testing.T) {
+	var gotPort uint16
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		var prefs Preferences
+		json.Unmarshal([]byte(r.FormValue("json")), &prefs)
+		gotPort = prefs.ListenPort
+		w.WriteHeader(http.StatusOK)
+	})
+
+	if err := client.SetPreferences(context.Background(), Preferences{ListenPort: 12345}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	if gotPort != 12345 {
+		t.Errorf("server received listen_port = %d, want 12345", gotPort)
+	}
+}
+
+func TestGetPreferencesReauthsOn403(t *testing.T) {
+	loginCount := 0
+	prefsAttempts := 0
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case loginPath:
+			loginCount++
+			w.Write([]byte("Ok."))
+		case preferencesPath:
+			prefsAttempts++
+			if prefsAttempts == 1 {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			json.NewEncoder(w).Encode(Preferences{ListenPort: 51413})
+		}
+	})
+
+	prefs, err := client.GetPreferences(context.Background())
+	if err != nil {
+		t.Fatalf("GetPreferences() error = %v", err)
+	}
+	if prefs.ListenPort != 51413 {
+		t.Errorf("ListenPort = %d, want 51413", prefs.ListenPort)
+	}
+	if loginCount != 1 {
+		t.Errorf("loginCount = %d, want 1 relogin after a 403", loginCount)
+	}
+	if prefsAttempts != 2 {
+		t.Errorf("prefsAttempts = %d, want 2 (initial + retry)", prefsAttempts)
+	}
+}
+
+func TestGetPreferencesDoesNotRetryReloginOnRejectedCredentials(t *testing.T) {
+	loginCount := 0
+	prefsAttempts := 0
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case loginPath:
+			loginCount++
+			w.Write([]byte("Fails."))
+		case preferencesPath:
+			prefsAttempts++
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})
+
+	_, err := client.GetPreferences(context.Background())
+	if err == nil {
+		t.Fatal("GetPreferences() error = nil, want an error")
+	}
+
+	var qErr *Error
+	if !errors.As(err, &qErr) {
+		t.Fatalf("GetPreferences() error = %v, want *Error", err)
+	}
+	if qErr.Class != ErrClassAuth {
+		t.Errorf("error class = %v, want %v", qErr.Class, ErrClassAuth)
+	}
+	if loginCount != 1 {
+		t.Errorf("loginCount = %d, want 1 (rejected credentials should not be retried)", loginCount)
+	}
+	if prefsAttempts != 1 {
+		t.Errorf("prefsAttempts = %d, want 1 (no retry after the relogin was abandoned)", prefsAttempts)
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v4.6.3"))
+	})
+
+	version, err := client.GetVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetVersion() error = %v", err)
+	}
+	if version != "v4.6.3" {
+		t.Errorf("GetVersion() = %q, want %q", version, "v4.6.3")
+	}
+}
+
+func TestAPIErrorIsClassified(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := client.GetPreferences(context.Background())
+	if err == nil {
+		t.Fatal("GetPreferences() error = nil, want an error")
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetPreferences() error = %v, want *Error", err)
+	}
+	if apiErr.Class != ErrClassAPI {
+		t.Errorf("error class = %v, want %v", apiErr.Class, ErrClassAPI)
+	}
+}
@@ -0,0 +1,42 @@
+package qbittorrent
+
+import "fmt"
+
+// ErrorClass categorizes a Client failure so callers can decide how to react, e.g. retrying
+// network errors but not rejected credentials.
+type ErrorClass int
+
+const (
+	ErrClassUnknown ErrorClass = iota
+	ErrClassNetwork
+	ErrClassAuth
+	ErrClassAPI
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrClassNetwork:
+		return "network"
+	case ErrClassAuth:
+		return "auth"
+	case ErrClassAPI:
+		return "api"
+	default:
+		return "unknown"
+	}
+}
+
+// Error wraps a failed Client call with the operation that failed and its ErrorClass.
+type Error struct {
+	Class ErrorClass
+	Op    string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("qbittorrent: %s: %s: %s", e.Op, e.Class, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,44 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// TransferInfo reports qBittorrent's current global transfer speeds and connection status.
+type TransferInfo struct {
+	DlInfoSpeed      int64  `json:"dl_info_speed"`
+	UpInfoSpeed      int64  `json:"up_info_speed"`
+	ConnectionStatus string `json:"connection_status"`
+}
+
+// GetVersion returns the qBittorrent application version string, e.g. "v4.6.3".
+func (c *Client) GetVersion(ctx context.Context) (string, error) {
+	resp, err := c.get(ctx, versionPath, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", &Error{Class: ErrClassAPI, Op: "GetVersion", Err: err}
+	}
+	return string(body), nil
+}
+
+// GetTransferInfo fetches qBittorrent's current global transfer info.
+func (c *Client) GetTransferInfo(ctx context.Context) (TransferInfo, error) {
+	resp, err := c.get(ctx, transferInfoPath, nil)
+	if err != nil {
+		return TransferInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	var info TransferInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return TransferInfo{}, &Error{Class: ErrClassAPI, Op: "GetTransferInfo", Err: err}
+	}
+	return info, nil
+}
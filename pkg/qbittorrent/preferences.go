@@ -0,0 +1,44 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// Preferences holds the subset of qBittorrent's application preferences this project cares
+// about. SetPreferences only needs to send the fields being changed; qBittorrent merges them
+// into the existing preferences server-side, so zero-valued fields here are harmless.
+type Preferences struct {
+	ListenPort uint16 `json:"listen_port"`
+}
+
+// GetPreferences fetches qBittorrent's current application preferences.
+func (c *Client) GetPreferences(ctx context.Context) (Preferences, error) {
+	resp, err := c.get(ctx, preferencesPath, nil)
+	if err != nil {
+		return Preferences{}, err
+	}
+	defer resp.Body.Close()
+
+	var prefs Preferences
+	if err := json.NewDecoder(resp.Body).Decode(&prefs); err != nil {
+		return Preferences{}, &Error{Class: ErrClassAPI, Op: "GetPreferences", Err: err}
+	}
+	return prefs, nil
+}
+
+// SetPreferences updates qBittorrent's application preferences with prefs.
+func (c *Client) SetPreferences(ctx context.Context, prefs Preferences) error {
+	payload, err := json.Marshal(prefs)
+	if err != nil {
+		return &Error{Class: ErrClassAPI, Op: "SetPreferences", Err: err}
+	}
+
+	resp, err := c.postForm(ctx, setPreferencesPath, url.Values{"json": {string(payload)}})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,27 @@
+package qbittorrent
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/internal/retry"
+)
+
+// reauthRetry governs retries of Login after a session expires (403/Unauthorized), in case
+// the failure is a transient network blip rather than rejected credentials. It only retries
+// ErrClassNetwork failures -- a rejected-credentials (ErrClassAuth) error will never succeed on
+// retry.
+var reauthRetry = retry.Policy{
+	Base:        250 * time.Millisecond,
+	Max:         5 * time.Second,
+	MaxAttempts: 3,
+	Retryable:   isRetryableReauthErr,
+}
+
+func isRetryableReauthErr(err error) bool {
+	var qErr *Error
+	if errors.As(err, &qErr) {
+		return qErr.Class == ErrClassNetwork
+	}
+	return true
+}
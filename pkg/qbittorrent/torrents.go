@@ -0,0 +1,94 @@
+package qbittorrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// Torrent is a single entry returned by GetTorrents.
+type Torrent struct {
+	Hash     string `json:"hash"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Tags     string `json:"tags"`
+	State    string `json:"state"`
+}
+
+// TorrentFilter narrows the results of GetTorrents to torrents matching any of Categories,
+// any of Tags, or any of Hashes. A zero-valued TorrentFilter matches every torrent. qBittorrent's
+// own API only accepts a single category/tag per request, so multi-value category and tag
+// matching is done client-side; hashes are already OR'd together by the API.
+type TorrentFilter struct {
+	Categories []string
+	Tags       []string
+	Hashes     []string
+}
+
+// IsEmpty reports whether the filter matches every torrent.
+func (f TorrentFilter) IsEmpty() bool {
+	return len(f.Categories) == 0 && len(f.Tags) == 0 && len(f.Hashes) == 0
+}
+
+func (f TorrentFilter) matches(t Torrent) bool {
+	if len(f.Categories) > 0 && !containsString(f.Categories, t.Category) {
+		return false
+	}
+	if len(f.Tags) > 0 && !anyTagMatches(t.Tags, f.Tags) {
+		return false
+	}
+	if len(f.Hashes) > 0 && !containsString(f.Hashes, t.Hash) {
+		return false
+	}
+	return true
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// anyTagMatches reports whether any of wanted appears in qBittorrent's comma-separated tags.
+func anyTagMatches(tags string, wanted []string) bool {
+	for _, have := range strings.Split(tags, ",") {
+		if containsString(wanted, strings.TrimSpace(have)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTorrents lists torrents matching filter.
+func (c *Client) GetTorrents(ctx context.Context, filter TorrentFilter) ([]Torrent, error) {
+	query := url.Values{}
+	if len(filter.Hashes) > 0 {
+		query.Set("hashes", strings.Join(filter.Hashes, "|"))
+	}
+
+	resp, err := c.get(ctx, torrentsInfoPath, query)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var torrents []Torrent
+	if err := json.NewDecoder(resp.Body).Decode(&torrents); err != nil {
+		return nil, &Error{Class: ErrClassAPI, Op: "GetTorrents", Err: err}
+	}
+
+	if filter.IsEmpty() {
+		return torrents, nil
+	}
+	filtered := make([]Torrent, 0, len(torrents))
+	for _, t := range torrents {
+		if filter.matches(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
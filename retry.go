@@ -0,0 +1,8 @@
+package main
+
+import "github.com/JacksonWrath/gluetun-qbt-port-updater/internal/retry"
+
+// RetryPolicy bounds retries of a transient Gluetun/qBittorrent operation using capped
+// exponential backoff with full jitter. The implementation lives in internal/retry, shared with
+// pkg/qbittorrent's re-authentication retry.
+type RetryPolicy = retry.Policy
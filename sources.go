@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/metrics"
+)
+
+// GluetunPoller is a PortSource that periodically polls Gluetun's control server for the
+// currently forwarded port. It only emits a value when the reported port changes, so
+// downstream sinks aren't re-triggered on every tick.
+type GluetunPoller struct {
+	baseURL    string
+	interval   time.Duration
+	client     *http.Client
+	metrics    *metrics.Metrics
+	retry      RetryPolicy
+	fatalAfter time.Duration
+}
+
+// NewGluetunPoller builds a GluetunPoller against baseURL, polling every interval. Failed
+// fetches are retried per retry; if they keep failing for fatalAfter, the process exits rather
+// than silently polling a Gluetun that may never recover.
+func NewGluetunPoller(baseURL string, interval time.Duration, m *metrics.Metrics, retry RetryPolicy, fatalAfter time.Duration) *GluetunPoller {
+	return &GluetunPoller{baseURL: baseURL, interval: interval, client: http.DefaultClient, metrics: m, retry: retry, fatalAfter: fatalAfter}
+}
+
+func (g *GluetunPoller) Watch(ctx context.Context) (<-chan uint16, error) {
+	out := make(chan uint16)
+
+	go func() {
+		defer close(out)
+
+		t := time.NewTicker(g.interval)
+		defer t.Stop()
+
+		failures := NewFailureWindow(g.fatalAfter)
+		var lastPort uint16
+		for {
+			var port uint16
+			err := g.retry.Do(ctx, func() error {
+				p, err := g.fetchPort(ctx)
+				if err != nil {
+					return err
+				}
+				port = p
+				return nil
+			})
+
+			if err != nil {
+				logger.Error("failed to fetch forwarded port from gluetun", slog.Any("err", err))
+				g.metrics.RecordGluetunError()
+				if failures.RecordFailure() {
+					log.Fatalf("gluetun has been unreachable for over %s, giving up: %s", g.fatalAfter, err)
+				}
+			} else {
+				failures.RecordSuccess()
+				g.metrics.RecordGluetunSuccess()
+				if port != lastPort {
+					lastPort = port
+					select {
+					case out <- port:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (g *GluetunPoller) fetchPort(ctx context.Context) (uint16, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+PORT_FORWARD_API, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var pfr portForwardResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pfr); err != nil {
+		return 0, fmt.Errorf("decoding forwarded port response: %w", err)
+	}
+	return pfr.Port, nil
+}
+
+// WebhookSource is a PortSource that listens for push notifications from an external
+// supervisor instead of polling Gluetun directly, e.g. a script invoked by Gluetun's VPN `up`
+// hook. This is useful when the control server isn't reachable from this process.
+type WebhookSource struct {
+	addr string
+}
+
+// NewWebhookSource builds a WebhookSource that listens on addr.
+func NewWebhookSource(addr string) *WebhookSource {
+	return &WebhookSource{addr: addr}
+}
+
+func (w *WebhookSource) Watch(ctx context.Context) (<-chan uint16, error) {
+	out := make(chan uint16)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/port", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var payload portForwardResponse
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			http.Error(rw, fmt.Sprintf("invalid body: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case out <- payload.Port:
+			rw.WriteHeader(http.StatusNoContent)
+		case <-req.Context().Done():
+		}
+	})
+
+	srv := &http.Server{Addr: w.addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	go func() {
+		defer close(out)
+		logger.Info("listening for port push notifications", slog.String("addr", w.addr))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("webhook server stopped", slog.Any("err", err))
+		}
+	}()
+
+	return out, nil
+}
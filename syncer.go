@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/metrics"
+)
+
+// PortSource produces a stream of forwarded-port observations. Implementations decide how
+// those observations are obtained: polling Gluetun's control server, accepting a push from an
+// external supervisor, watching a file written by Gluetun's `up` script, etc. Watch must close
+// the returned channel once it stops producing values.
+type PortSource interface {
+	Watch(ctx context.Context) (<-chan uint16, error)
+}
+
+// PortSink applies a forwarded port somewhere, e.g. to a torrent client's listen port.
+type PortSink interface {
+	Apply(ctx context.Context, port uint16) error
+}
+
+// PortSyncer wires a PortSource to a PortSink, applying every port change the source emits.
+type PortSyncer struct {
+	source  PortSource
+	sink    PortSink
+	metrics *metrics.Metrics
+
+	lastPort uint16
+}
+
+// NewPortSyncer builds a PortSyncer reading from source and writing to sink.
+func NewPortSyncer(source PortSource, sink PortSink, m *metrics.Metrics) *PortSyncer {
+	return &PortSyncer{source: source, sink: sink, metrics: m}
+}
+
+// SeedLastPort sets the port the syncer considers already applied, without applying it to sink.
+// Call it before Run with a target's currently configured port, so a process restart doesn't
+// needlessly re-apply a port that's already correct.
+func (s *PortSyncer) SeedLastPort(port uint16) {
+	s.lastPort = port
+}
+
+// Run blocks, applying forwarded-port changes until ctx is cancelled or the source stops
+// producing values.
+func (s *PortSyncer) Run(ctx context.Context) error {
+	ports, err := s.source.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case port, ok := <-ports:
+			if !ok {
+				return nil
+			}
+			if err := s.handlePort(ctx, port); err != nil {
+				logger.Error("failed to apply forwarded port", slog.Int("port", int(port)), slog.Any("err", err))
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *PortSyncer) handlePort(ctx context.Context, port uint16) error {
+	if port == s.lastPort {
+		return nil
+	}
+	if port == 0 {
+		// Gluetun reports 0 while the VPN is still negotiating a forwarded port.
+		logger.Debug("ignoring zero port, VPN still negotiating")
+		return nil
+	}
+
+	logger.Info("forwarded port changed", slog.Int("previous-port", int(s.lastPort)), slog.Int("port", int(port)))
+	s.metrics.RecordPortObserved(port)
+	if err := s.sink.Apply(ctx, port); err != nil {
+		return err
+	}
+	s.lastPort = port
+	return nil
+}
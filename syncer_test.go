@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/metrics"
+)
+
+type fakeSink struct {
+	applied []uint16
+	err     error
+}
+
+func (f *fakeSink) Apply(ctx context.Context, port uint16) error {
+	f.applied = append(f.applied, port)
+	return f.err
+}
+
+func TestHandlePortIgnoresZero(t *testing.T) {
+	sink := &fakeSink{}
+	s := NewPortSyncer(nil, sink, metrics.New(0))
+
+	if err := s.handlePort(context.Background(), 0); err != nil {
+		t.Fatalf("handlePort(0) error = %v", err)
+	}
+	if len(sink.applied) != 0 {
+		t.Errorf("sink.applied = %v, want none (port 0 means VPN still negotiating)", sink.applied)
+	}
+}
+
+func TestHandlePortDedupesRepeatedPort(t *testing.T) {
+	sink := &fakeSink{}
+	s := NewPortSyncer(nil, sink, metrics.New(0))
+
+	if err := s.handlePort(context.Background(), 51413); err != nil {
+		t.Fatalf("handlePort() error = %v", err)
+	}
+	if err := s.handlePort(context.Background(), 51413); err != nil {
+		t.Fatalf("handlePort() error = %v", err)
+	}
+
+	if len(sink.applied) != 1 {
+		t.Errorf("sink.applied = %v, want a single apply for an unchanged port", sink.applied)
+	}
+}
+
+func TestHandlePortAppliesChangedPort(t *testing.T) {
+	sink := &fakeSink{}
+	s := NewPortSyncer(nil, sink, metrics.New(0))
+
+	if err := s.handlePort(context.Background(), 51413); err != nil {
+		t.Fatalf("handlePort() error = %v", err)
+	}
+	if err := s.handlePort(context.Background(), 51414); err != nil {
+		t.Fatalf("handlePort() error = %v", err)
+	}
+
+	if want := []uint16{51413, 51414}; len(sink.applied) != len(want) || sink.applied[0] != want[0] || sink.applied[1] != want[1] {
+		t.Errorf("sink.applied = %v, want %v", sink.applied, want)
+	}
+}
+
+func TestSeedLastPortSkipsReapplyingAnUnchangedPort(t *testing.T) {
+	sink := &fakeSink{}
+	s := NewPortSyncer(nil, sink, metrics.New(0))
+	s.SeedLastPort(51413)
+
+	if err := s.handlePort(context.Background(), 51413); err != nil {
+		t.Fatalf("handlePort() error = %v", err)
+	}
+	if len(sink.applied) != 0 {
+		t.Errorf("sink.applied = %v, want none (seeded port already matches)", sink.applied)
+	}
+}
+
+func TestHandlePortPropagatesSinkError(t *testing.T) {
+	wantErr := errors.New("apply failed")
+	sink := &fakeSink{err: wantErr}
+	s := NewPortSyncer(nil, sink, metrics.New(0))
+
+	if err := s.handlePort(context.Background(), 51413); !errors.Is(err, wantErr) {
+		t.Fatalf("handlePort() error = %v, want %v", err, wantErr)
+	}
+	if s.lastPort != 0 {
+		t.Errorf("lastPort = %d, want 0 (a failed apply must not update lastPort)", s.lastPort)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/JacksonWrath/gluetun-qbt-port-updater/pkg/qbittorrent"
+)
+
+// HealthState summarizes whether a Target is currently able to apply port updates.
+type HealthState int
+
+const (
+	HealthUnknown HealthState = iota
+	HealthHealthy
+	HealthDegraded
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthHealthy:
+		return "healthy"
+	case HealthDegraded:
+		return "degraded"
+	default:
+		return "unknown"
+	}
+}
+
+// Health is a snapshot of a Target's recent apply attempts.
+type Health struct {
+	State               HealthState
+	LastError           error
+	LastSuccess         time.Time
+	ConsecutiveFailures int
+}
+
+// Target is a single endpoint a Dispatcher can apply a forwarded port to. It is an interface so
+// Dispatcher's parallel fan-out and health tracking can be tested against a fake, without
+// talking to a real qBittorrent instance.
+type Target interface {
+	// Name identifies the target in logs and metrics.
+	Name() string
+	// Apply applies port to the target.
+	Apply(ctx context.Context, port uint16) error
+	// CurrentListenPort fetches the target's currently configured listen port.
+	CurrentListenPort(ctx context.Context) (uint16, error)
+	// Health returns a snapshot of the target's current health.
+	Health() Health
+}
+
+// qbtTarget is the Target implementation backing a real qBittorrent instance, optionally gated
+// to a subset of torrents by category, tag, or hash: see Apply.
+type qbtTarget struct {
+	name   string
+	client *qbittorrent.Client
+	filter qbittorrent.TorrentFilter
+
+	mu     sync.Mutex
+	health Health
+}
+
+// NewTarget builds a Target backed by client, optionally gated by filter.
+func NewTarget(name string, client *qbittorrent.Client, filter qbittorrent.TorrentFilter) Target {
+	return &qbtTarget{name: name, client: client, filter: filter}
+}
+
+func (t *qbtTarget) Name() string {
+	return t.name
+}
+
+// CurrentListenPort fetches qBittorrent's currently configured listen port for this target.
+func (t *qbtTarget) CurrentListenPort(ctx context.Context) (uint16, error) {
+	prefs, err := t.client.GetPreferences(ctx)
+	return prefs.ListenPort, err
+}
+
+func (t *qbtTarget) Health() Health {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.health
+}
+
+func (t *qbtTarget) recordResult(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.health.LastError = err
+		t.health.ConsecutiveFailures++
+		t.health.State = HealthDegraded
+		return
+	}
+	t.health.LastError = nil
+	t.health.ConsecutiveFailures = 0
+	t.health.LastSuccess = time.Now()
+	t.health.State = HealthHealthy
+}
+
+// Apply sets port as qBittorrent's listen port. qBittorrent's listen_port is a single
+// instance-wide preference, so a non-empty filter does not scope the update to individual
+// torrents -- it only gates the entire update on whether any torrent currently matches the
+// filter, skipping it entirely when none do.
+func (t *qbtTarget) Apply(ctx context.Context, port uint16) error {
+	if !t.filter.IsEmpty() {
+		torrents, err := t.client.GetTorrents(ctx, t.filter)
+		if err != nil {
+			t.recordResult(err)
+			return err
+		}
+		if len(torrents) == 0 {
+			logger.Debug("no torrents match filter, skipping instance-wide port update", slog.String("target", t.name))
+			t.recordResult(nil)
+			return nil
+		}
+	}
+
+	err := t.client.SetPreferences(ctx, qbittorrent.Preferences{ListenPort: port})
+	t.recordResult(err)
+	return err
+}